@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"image/color"
 	"log"
 	"math"
 	"math/cmplx"
@@ -11,6 +14,8 @@ import (
 	"sync"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/Londones/fractalRenderer/palette"
 )
 
 type Complex struct {
@@ -26,6 +31,19 @@ type JuliaParams struct {
 	MaxIterations int     `json:"maxIterations"`
 	Width         int     `json:"width"`
 	Height        int     `json:"height"`
+	// Precision selects the escape-time algorithm: "float64" (default) or
+	// "perturbation" for zooms beyond what float64 can resolve. See
+	// calculatePerturbationTile in perturbation.go.
+	Precision string `json:"precision"`
+	// PaletteName looks up a palette in the registry (see the palette
+	// package), taking priority over the legacy numeric Coloring selector
+	// above.
+	PaletteName string `json:"paletteName"`
+	// Histogram colors each pixel by its position in the whole frame's
+	// iteration-count CDF instead of its raw i/maxIterations fraction.
+	// Histogram frames are rendered as a single two-pass barrier instead
+	// of streaming tile-by-tile - see renderHistogramFrame.
+	Histogram bool `json:"histogram"`
 }
 
 type Offset struct {
@@ -33,47 +51,287 @@ type Offset struct {
 	Y float64 `json:"y"`
 }
 
-func calculateJuliaSet(params JuliaParams) []byte {
-	data := make([]byte, params.Width*params.Height*4)
+// Tile is a rectangular sub-region of a frame, in pixel coordinates of the
+// full image.
+type Tile struct {
+	X, Y, W, H int
+}
+
+const (
+	tileSize       = 128
+	tileHeaderSize = 20 // tileX, tileY, w, h, frameID as uint32
+	tileQueueSize  = 256
+)
+
+// tileJob is one tile's worth of work handed to the worker pool. ctx is the
+// frame's cancellation context: a job whose ctx is already done when a
+// worker picks it up is dropped without rendering.
+type tileJob struct {
+	ctx     context.Context
+	frameID uint64
+	params  JuliaParams
+	tile    Tile
+	conn    *websocket.Conn
+	writeMu *sync.Mutex
+}
+
+// tileIterResult is one tile's raw escape-time output, before coloring.
+// renderHistogramFrame collects one of these per tile so every pixel in
+// the frame can be pooled into a single CDF before any of them are
+// colored.
+type tileIterResult struct {
+	tile  Tile
+	iters []int
+	zs    []complex128
+}
+
+var tileQueue = make(chan tileJob, tileQueueSize)
+
+// startTileWorkers launches the fixed-size tile-rendering pool once at
+// startup. Workers live for the lifetime of the process and are shared by
+// every connection, rather than being spawned per frame.
+func startTileWorkers() {
+	workers := runtime.NumCPU()
+	for i := 0; i < workers; i++ {
+		go tileWorker()
+	}
+}
+
+func tileWorker() {
+	for job := range tileQueue {
+		select {
+		case <-job.ctx.Done():
+			continue
+		default:
+		}
+
+		pixels, ok := activeRenderer.RenderTile(job.params, job.tile, job.ctx)
+		if !ok {
+			continue
+		}
+
+		if err := sendTileMessage(job.conn, job.writeMu, job.frameID, job.tile, pixels); err != nil {
+			log.Printf("Error writing tile: %v", err)
+		}
+	}
+}
+
+// sendTileMessage packs one tile's header and pixel bytes and writes them
+// as a single binary websocket message. Shared by tileWorker's normal
+// tile-by-tile streaming and renderHistogramFrame's two-pass path.
+func sendTileMessage(conn *websocket.Conn, writeMu *sync.Mutex, frameID uint64, tile Tile, pixels []byte) error {
+	message := make([]byte, tileHeaderSize+len(pixels))
+	binary.BigEndian.PutUint32(message[0:4], uint32(tile.X))
+	binary.BigEndian.PutUint32(message[4:8], uint32(tile.Y))
+	binary.BigEndian.PutUint32(message[8:12], uint32(tile.W))
+	binary.BigEndian.PutUint32(message[12:16], uint32(tile.H))
+	binary.BigEndian.PutUint32(message[16:20], uint32(frameID))
+	copy(message[tileHeaderSize:], pixels)
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	return conn.WriteMessage(websocket.BinaryMessage, message)
+}
+
+// computeJuliaTile runs the escape-time loop over tile's pixels, using
+// params.Width and params.Height to compute the complex-plane mapping for
+// the full frame, stopping short of coloring the result. It returns
+// ok=false if ctx is cancelled before the tile finishes, in which case
+// iters and zs are nil.
+func computeJuliaTile(params JuliaParams, tile Tile, ctx context.Context) (iters []int, zs []complex128, ok bool) {
+	iters = make([]int, tile.W*tile.H)
+	zs = make([]complex128, tile.W*tile.H)
 	c := complex(params.C.Real, params.C.Imag)
 
-	var wg sync.WaitGroup
-	numGoroutines := runtime.NumCPU()
-	rowsPerGoroutine := params.Height / numGoroutines
+	for ty := 0; ty < tile.H; ty++ {
+		if ty%16 == 0 {
+			select {
+			case <-ctx.Done():
+				return nil, nil, false
+			default:
+			}
+		}
 
-	for i := 0; i < numGoroutines; i++ {
-		wg.Add(1)
-		go func(startY, endY int) {
-			defer wg.Done()
-			for y := startY; y < endY; y++ {
-				imag := float64(y)/params.Zoom - float64(params.Height)/(2*params.Zoom) + params.Center.Imag
-				for x := 0; x < params.Width; x++ {
-					real := float64(x)/params.Zoom - float64(params.Width)/(2*params.Zoom) + params.Center.Real
-					z := complex(real, imag)
-
-					var i int
-					for i = 0; i < params.MaxIterations; i++ {
-						if cmplx.Abs(z) > 2 {
-							break
-						}
-						z = z*z + c
-					}
-
-					index := (y*params.Width + x) * 4
-					color := ReturnRGBA(params.Coloring, i, params.MaxIterations, z, x, y)
-					data[index] = color.R
-					data[index+1] = color.G
-					data[index+2] = color.B
-					data[index+3] = color.A
+		y := tile.Y + ty
+		imag := float64(y)/params.Zoom - float64(params.Height)/(2*params.Zoom) + params.Center.Imag
+		for tx := 0; tx < tile.W; tx++ {
+			x := tile.X + tx
+			real := float64(x)/params.Zoom - float64(params.Width)/(2*params.Zoom) + params.Center.Real
+			z := complex(real, imag)
+
+			var i int
+			for i = 0; i < params.MaxIterations; i++ {
+				if cmplx.Abs(z) > 2 {
+					break
 				}
+				z = z*z + c
 			}
-		}(i*rowsPerGoroutine, int(math.Min(float64((i+1)*rowsPerGoroutine), float64(params.Height))))
+
+			idx := ty*tile.W + tx
+			iters[idx] = i
+			zs[idx] = z
+		}
+	}
+
+	return iters, zs, true
+}
+
+// calculateJuliaTile renders just the pixels inside tile, coloring it on
+// its own rather than against a frame-wide CDF. It returns ok=false if ctx
+// is cancelled before the tile finishes, in which case data is nil and
+// should not be sent to the client.
+func calculateJuliaTile(params JuliaParams, tile Tile, ctx context.Context) (data []byte, ok bool) {
+	iters, zs, ok := computeJuliaTile(params, tile, ctx)
+	if !ok {
+		return nil, false
+	}
+	return colorTile(params, iters, zs, nil), true
+}
+
+// colorTile turns per-pixel iteration counts and final z values into RGBA
+// bytes. cdf is nil unless the caller has already equalized iteration
+// counts across a whole frame (see renderHistogramFrame) - colorTile never
+// builds one itself, since a CDF built from a single tile's pixels would
+// disagree with its neighbors at every tile boundary.
+func colorTile(params JuliaParams, iters []int, zs []complex128, cdf []float64) []byte {
+	data := make([]byte, len(iters)*4)
+
+	for idx, i := range iters {
+		var col color.RGBA
+		if i == params.MaxIterations {
+			col = color.RGBA{0, 0, 0, 255}
+		} else if cdf != nil {
+			col = ReturnRGBAAtT(params.PaletteName, params.Coloring, cdf[i])
+		} else {
+			col = ReturnRGBA(params.PaletteName, params.Coloring, i, params.MaxIterations, zs[idx])
+		}
+
+		index := idx * 4
+		data[index] = col.R
+		data[index+1] = col.G
+		data[index+2] = col.B
+		data[index+3] = col.A
 	}
 
-	wg.Wait()
 	return data
 }
 
+// enqueueFrame splits params into tileSize x tileSize tiles and pushes them
+// onto the shared tile queue, tagged with frameID and ctx so stale tiles can
+// be dropped by the workers if a newer frame supersedes this one.
+//
+// Histogram frames can't stream this way: a tile can't be colored until
+// every other tile in the frame has contributed to the shared CDF, so they
+// take the two-pass path in renderHistogramFrame instead.
+func enqueueFrame(ctx context.Context, frameID uint64, params JuliaParams, conn *websocket.Conn, writeMu *sync.Mutex) {
+	if params.Histogram {
+		renderHistogramFrame(ctx, frameID, params, conn, writeMu)
+		return
+	}
+
+	for y := 0; y < params.Height; y += tileSize {
+		h := int(math.Min(tileSize, float64(params.Height-y)))
+		for x := 0; x < params.Width; x += tileSize {
+			w := int(math.Min(tileSize, float64(params.Width-x)))
+			tileQueue <- tileJob{
+				ctx:     ctx,
+				frameID: frameID,
+				params:  params,
+				tile:    Tile{X: x, Y: y, W: w, H: h},
+				conn:    conn,
+				writeMu: writeMu,
+			}
+		}
+	}
+}
+
+// renderHistogramFrame renders a params.Histogram frame as a single
+// two-pass barrier: every tile's iteration counts are computed up front
+// and pooled into one frame-wide CDF, then every tile is colored against
+// that shared CDF and sent. This trades the progressive tile-by-tile
+// streaming enqueueFrame otherwise gives for a histogram that agrees with
+// itself across the whole frame instead of seaming at tile boundaries.
+func renderHistogramFrame(ctx context.Context, frameID uint64, params JuliaParams, conn *websocket.Conn, writeMu *sync.Mutex) {
+	var tiles []Tile
+	for y := 0; y < params.Height; y += tileSize {
+		h := int(math.Min(tileSize, float64(params.Height-y)))
+		for x := 0; x < params.Width; x += tileSize {
+			w := int(math.Min(tileSize, float64(params.Width-x)))
+			tiles = append(tiles, Tile{X: x, Y: y, W: w, H: h})
+		}
+	}
+
+	results := make([]tileIterResult, len(tiles))
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for i, tile := range tiles {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, tile Tile) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			iters, zs, ok := activeRenderer.ComputeTile(params, tile, ctx)
+			if !ok {
+				return
+			}
+			results[i] = tileIterResult{tile: tile, iters: iters, zs: zs}
+		}(i, tile)
+	}
+	wg.Wait()
+
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+
+	totalPixels := 0
+	for _, res := range results {
+		totalPixels += len(res.iters)
+	}
+	allIters := make([]int, 0, totalPixels)
+	for _, res := range results {
+		allIters = append(allIters, res.iters...)
+	}
+	cdf := palette.HistogramEqualize(allIters, params.MaxIterations)
+
+	for _, res := range results {
+		if res.iters == nil {
+			continue
+		}
+		pixels := colorTile(params, res.iters, res.zs, cdf)
+		if err := sendTileMessage(conn, writeMu, frameID, res.tile, pixels); err != nil {
+			log.Printf("Error writing tile: %v", err)
+		}
+	}
+}
+
+// frameCancel tracks the cancellation func for whichever frame or
+// animation is currently in flight on a connection, so a new one can tear
+// down the last one. Threading a bare context.CancelFunc through loop
+// iterations leaves go vet's lostcancel check unable to prove it's always
+// called; storing it in a field via replace gives vet a single place to
+// see it escape instead.
+type frameCancel struct {
+	cancel context.CancelFunc
+}
+
+// replace cancels whatever was previously tracked and starts a new
+// cancelable context derived from ctx.
+func (f *frameCancel) replace(ctx context.Context) context.Context {
+	f.stop()
+	ctx, cancel := context.WithCancel(ctx)
+	f.cancel = cancel
+	return ctx
+}
+
+func (f *frameCancel) stop() {
+	if f.cancel != nil {
+		f.cancel()
+	}
+}
+
 func wsHandler(w http.ResponseWriter, r *http.Request) {
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  1024,
@@ -90,6 +348,11 @@ func wsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
+	var writeMu sync.Mutex
+	var frameID uint64
+	var fc frameCancel
+	defer fc.stop()
+
 	for {
 		_, p, err := conn.ReadMessage()
 		if err != nil {
@@ -98,28 +361,56 @@ func wsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		var request struct {
-			Params JuliaParams `json:"params"`
+			Type      string           `json:"type"`
+			Params    JuliaParams      `json:"params"`
+			Name      string           `json:"name"`
+			Gradient  palette.Gradient `json:"gradient"`
+			Keyframes []Keyframe       `json:"keyframes"`
+			FPS       int              `json:"fps"`
+			Format    string           `json:"format"`
 		}
 		err = json.Unmarshal(p, &request)
 		if err != nil {
 			log.Printf("Error unmarshaling JSON: %v", err)
-			return
+			continue
 		}
 
-		params := request.Params
+		switch request.Type {
+		case "cancel":
+			fc.stop()
+			continue
+		case "palette":
+			gradient, err := palette.BuildGradientPalette(request.Gradient)
+			if err != nil {
+				log.Printf("Error building gradient palette: %v", err)
+				continue
+			}
+			palette.RegisterPalette(request.Name, gradient)
+			continue
+		case "animate":
+			ctx := fc.replace(context.Background())
+			go runAnimation(ctx, conn, &writeMu, AnimateRequest{
+				Keyframes: request.Keyframes,
+				FPS:       request.FPS,
+				Format:    request.Format,
+			})
+			continue
+		}
 
-		juliaData := calculateJuliaSet(params)
+		// A fresh set of params invalidates whatever frame is currently
+		// in flight.
+		frameID++
+		ctx := fc.replace(context.Background())
 
-		err = conn.WriteMessage(websocket.BinaryMessage, juliaData)
-		if err != nil {
-			log.Printf("Error sending julia data: %v", err)
-			return
-		}
+		enqueueFrame(ctx, frameID, request.Params, conn, &writeMu)
 	}
 }
 
 func main() {
+	selectRenderer()
+	startTileWorkers()
 	http.HandleFunc("/ws", wsHandler)
+	http.HandleFunc("/tile/", tileHandler)
 	fmt.Println("Server is running on :8080")
 	http.ListenAndServe(":8080", nil)
 }
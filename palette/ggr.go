@@ -0,0 +1,163 @@
+package palette
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// ggrSegment is one piece of a GIMP gradient: control points left <= mid
+// <= right in [0, 1], the endpoint colors, and how to blend between them.
+type ggrSegment struct {
+	left, mid, right      float64
+	leftColor, rightColor [4]float64 // r, g, b, a in [0, 1]
+	blending              int        // 0 linear, 1 curved, 2 sinusoidal
+	coloring              int        // 0 RGB, 1 HSV counter-clockwise, 2 HSV clockwise
+}
+
+type ggrPalette struct {
+	segments []ggrSegment
+}
+
+func (g ggrPalette) ColorAt(t float64) color.RGBA {
+	t = clamp01(t)
+	for _, seg := range g.segments {
+		if t >= seg.left && t <= seg.right {
+			return seg.colorAt(t)
+		}
+	}
+	if len(g.segments) == 0 {
+		return color.RGBA{0, 0, 0, 255}
+	}
+	return g.segments[len(g.segments)-1].colorAt(1)
+}
+
+func (seg ggrSegment) colorAt(t float64) color.RGBA {
+	pos := seg.midpointPosition(t)
+
+	switch seg.blending {
+	case 1: // curved
+		pos = math.Pow(pos, 2)
+	case 2: // sinusoidal
+		pos = (1 - math.Cos(pos*math.Pi)) / 2
+	}
+
+	if seg.coloring == 0 {
+		return lerpRGB(seg.leftColor, seg.rightColor, pos)
+	}
+	return lerpHSV(seg.leftColor, seg.rightColor, pos, seg.coloring == 1)
+}
+
+// midpointPosition re-maps t so 0.5 always lands on the segment's
+// midpoint control point, matching GIMP's two-piece linear ramp before
+// any curve/sinusoidal shaping is applied.
+func (seg ggrSegment) midpointPosition(t float64) float64 {
+	if t <= seg.mid {
+		span := seg.mid - seg.left
+		if span <= 0 {
+			return 0
+		}
+		return 0.5 * (t - seg.left) / span
+	}
+	span := seg.right - seg.mid
+	if span <= 0 {
+		return 1
+	}
+	return 0.5 + 0.5*(t-seg.mid)/span
+}
+
+func lerpRGB(a, b [4]float64, t float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(clamp01(a[0]+(b[0]-a[0])*t) * 255),
+		G: uint8(clamp01(a[1]+(b[1]-a[1])*t) * 255),
+		B: uint8(clamp01(a[2]+(b[2]-a[2])*t) * 255),
+		A: uint8(clamp01(a[3]+(b[3]-a[3])*t) * 255),
+	}
+}
+
+func lerpHSV(a, b [4]float64, t float64, ccw bool) color.RGBA {
+	ah, as, av := (colorful.Color{R: a[0], G: a[1], B: a[2]}).Hsv()
+	bh, bs, bv := (colorful.Color{R: b[0], G: b[1], B: b[2]}).Hsv()
+
+	if ccw {
+		if bh < ah {
+			bh += 360
+		}
+	} else if bh > ah {
+		bh -= 360
+	}
+
+	h := math.Mod(ah+(bh-ah)*t, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	alpha := clamp01(a[3] + (b[3]-a[3])*t)
+	out := colorful.Hsv(h, as+(bs-as)*t, av+(bv-av)*t)
+	outR, outG, outB := out.RGB255()
+	return color.RGBA{outR, outG, outB, uint8(alpha * 255)}
+}
+
+// ParseGGR reads a GIMP .ggr gradient file and returns it as a Palette.
+func ParseGGR(r io.Reader) (Palette, error) {
+	scanner := bufio.NewScanner(r)
+
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("palette: empty .ggr file")
+	}
+	if strings.TrimSpace(scanner.Text()) != "GIMP Gradient" {
+		return nil, fmt.Errorf("palette: not a GIMP gradient file")
+	}
+
+	line := ""
+	for scanner.Scan() {
+		line = strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "Name:") {
+			break
+		}
+	}
+
+	count, err := strconv.Atoi(line)
+	if err != nil {
+		return nil, fmt.Errorf("palette: invalid segment count %q: %w", line, err)
+	}
+
+	segments := make([]ggrSegment, 0, count)
+	for i := 0; i < count; i++ {
+		if !scanner.Scan() {
+			return nil, fmt.Errorf("palette: expected %d segments, found %d", count, i)
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 13 {
+			return nil, fmt.Errorf("palette: malformed segment line %q", scanner.Text())
+		}
+
+		nums := make([]float64, 13)
+		for j, f := range fields[:13] {
+			v, err := strconv.ParseFloat(f, 64)
+			if err != nil {
+				return nil, fmt.Errorf("palette: malformed segment field %q: %w", f, err)
+			}
+			nums[j] = v
+		}
+
+		segments = append(segments, ggrSegment{
+			left:       nums[0],
+			mid:        nums[1],
+			right:      nums[2],
+			leftColor:  [4]float64{nums[3], nums[4], nums[5], nums[6]},
+			rightColor: [4]float64{nums[7], nums[8], nums[9], nums[10]},
+			blending:   int(nums[11]),
+			coloring:   int(nums[12]),
+		})
+	}
+
+	return ggrPalette{segments: segments}, nil
+}
@@ -0,0 +1,74 @@
+package palette
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+const validGGR = `GIMP Gradient
+Name: Test
+1
+0.000000 0.500000 1.000000 0.000000 0.000000 0.000000 1.000000 1.000000 1.000000 1.000000 1.000000 0 0
+`
+
+func TestParseGGRValid(t *testing.T) {
+	p, err := ParseGGR(strings.NewReader(validGGR))
+	if err != nil {
+		t.Fatalf("ParseGGR: %v", err)
+	}
+	if got, want := p.ColorAt(0), (color.RGBA{0, 0, 0, 255}); got != want {
+		t.Errorf("ColorAt(0) = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseGGRNotAGradientFile(t *testing.T) {
+	if _, err := ParseGGR(strings.NewReader("not a gradient\n")); err == nil {
+		t.Fatal("expected an error for a file missing the GIMP Gradient header")
+	}
+}
+
+func TestParseGGREmptyFile(t *testing.T) {
+	if _, err := ParseGGR(strings.NewReader("")); err == nil {
+		t.Fatal("expected an error for an empty file")
+	}
+}
+
+func TestParseGGRInvalidSegmentCount(t *testing.T) {
+	input := "GIMP Gradient\nnot-a-number\n"
+	if _, err := ParseGGR(strings.NewReader(input)); err == nil {
+		t.Fatal("expected an error for a non-numeric segment count")
+	}
+}
+
+func TestParseGGRTruncatedSegments(t *testing.T) {
+	input := "GIMP Gradient\n2\n0.000000 0.500000 1.000000 0 0 0 1 1 1 1 1 0 0\n"
+	if _, err := ParseGGR(strings.NewReader(input)); err == nil {
+		t.Fatal("expected an error when fewer segment lines are present than the declared count")
+	}
+}
+
+func TestParseGGRMalformedSegmentLine(t *testing.T) {
+	input := "GIMP Gradient\n1\n0.0 0.5\n"
+	if _, err := ParseGGR(strings.NewReader(input)); err == nil {
+		t.Fatal("expected an error for a segment line with too few fields")
+	}
+}
+
+func TestGgrPaletteColorAtFallsBackPastLastSegment(t *testing.T) {
+	p := ggrPalette{segments: []ggrSegment{
+		{left: 0, mid: 0.5, right: 0.5, leftColor: [4]float64{0, 0, 0, 1}, rightColor: [4]float64{1, 1, 1, 1}},
+	}}
+	got := p.ColorAt(1)
+	want := p.segments[0].colorAt(1)
+	if got != want {
+		t.Errorf("ColorAt(1) = %+v, want %+v (should clamp to the last segment at t=1)", got, want)
+	}
+}
+
+func TestGgrPaletteColorAtNoSegments(t *testing.T) {
+	p := ggrPalette{}
+	if got, want := p.ColorAt(0.5), (color.RGBA{0, 0, 0, 255}); got != want {
+		t.Errorf("ColorAt on an empty palette = %+v, want black", got)
+	}
+}
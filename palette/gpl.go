@@ -0,0 +1,66 @@
+package palette
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// gplPalette is a GIMP .gpl indexed palette: a flat list of colors with no
+// interpolation. ColorAt picks entry int(t*(len-1)), rounding t's position
+// down, so the last entry is only returned at t == 1.
+type gplPalette struct {
+	colors []color.RGBA
+}
+
+func (g gplPalette) ColorAt(t float64) color.RGBA {
+	if len(g.colors) == 0 {
+		return color.RGBA{0, 0, 0, 255}
+	}
+	idx := int(clamp01(t) * float64(len(g.colors)-1))
+	return g.colors[idx]
+}
+
+// ParseGPL reads a GIMP .gpl indexed palette file and returns it as a
+// Palette.
+func ParseGPL(r io.Reader) (Palette, error) {
+	scanner := bufio.NewScanner(r)
+
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("palette: empty .gpl file")
+	}
+	if strings.TrimSpace(scanner.Text()) != "GIMP Palette" {
+		return nil, fmt.Errorf("palette: not a GIMP palette file")
+	}
+
+	var colors []color.RGBA
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "Name:") || strings.HasPrefix(line, "Columns:") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+
+		r, errR := strconv.Atoi(fields[0])
+		g, errG := strconv.Atoi(fields[1])
+		b, errB := strconv.Atoi(fields[2])
+		if errR != nil || errG != nil || errB != nil {
+			continue
+		}
+
+		colors = append(colors, color.RGBA{uint8(r), uint8(g), uint8(b), 255})
+	}
+
+	if len(colors) == 0 {
+		return nil, fmt.Errorf("palette: .gpl file had no colors")
+	}
+
+	return gplPalette{colors: colors}, nil
+}
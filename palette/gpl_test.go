@@ -0,0 +1,66 @@
+package palette
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+const validGPL = `GIMP Palette
+Name: Test
+Columns: 3
+# a comment
+255   0   0	Red
+  0 255   0	Green
+  0   0 255	Blue
+`
+
+func TestParseGPLValid(t *testing.T) {
+	p, err := ParseGPL(strings.NewReader(validGPL))
+	if err != nil {
+		t.Fatalf("ParseGPL: %v", err)
+	}
+	if got, want := p.ColorAt(0), (color.RGBA{255, 0, 0, 255}); got != want {
+		t.Errorf("ColorAt(0) = %+v, want %+v", got, want)
+	}
+	if got, want := p.ColorAt(1), (color.RGBA{0, 0, 255, 255}); got != want {
+		t.Errorf("ColorAt(1) = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseGPLNotAPaletteFile(t *testing.T) {
+	if _, err := ParseGPL(strings.NewReader("not a palette\n")); err == nil {
+		t.Fatal("expected an error for a file missing the GIMP Palette header")
+	}
+}
+
+func TestParseGPLEmptyFile(t *testing.T) {
+	if _, err := ParseGPL(strings.NewReader("")); err == nil {
+		t.Fatal("expected an error for an empty file")
+	}
+}
+
+func TestParseGPLNoColors(t *testing.T) {
+	input := "GIMP Palette\nName: Empty\n# just a comment\n"
+	if _, err := ParseGPL(strings.NewReader(input)); err == nil {
+		t.Fatal("expected an error for a palette with no color entries")
+	}
+}
+
+func TestParseGPLSkipsMalformedLines(t *testing.T) {
+	input := "GIMP Palette\nnot numbers here\n255 0 0\n"
+	p, err := ParseGPL(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseGPL: %v", err)
+	}
+	if got, want := p.ColorAt(0), (color.RGBA{255, 0, 0, 255}); got != want {
+		t.Errorf("ColorAt(0) = %+v, want %+v (the malformed line should be skipped, not fail the parse)", got, want)
+	}
+}
+
+func TestGplPaletteColorAtEmpty(t *testing.T) {
+	p := gplPalette{}
+	if got, want := p.ColorAt(0.5), (color.RGBA{0, 0, 0, 255}); got != want {
+		t.Errorf("ColorAt on an empty palette = %+v, want black", got)
+	}
+}
@@ -0,0 +1,107 @@
+package palette
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// GradientStop is one control point of a JSON gradient.
+type GradientStop struct {
+	Offset float64 `json:"offset"`
+	Color  string  `json:"color"` // "#rrggbb" or "#rrggbbaa"
+}
+
+// Gradient is the small JSON palette format clients can post over the
+// websocket instead of shipping a .ggr/.gpl file: a handful of stops,
+// linearly interpolated in RGB between neighbors.
+type Gradient struct {
+	Stops []GradientStop `json:"stops"`
+}
+
+type gradientStop struct {
+	offset float64
+	color  [4]float64
+}
+
+type gradientPalette struct {
+	stops []gradientStop
+}
+
+func (g gradientPalette) ColorAt(t float64) color.RGBA {
+	t = clamp01(t)
+	if len(g.stops) == 0 {
+		return color.RGBA{0, 0, 0, 255}
+	}
+
+	first, last := g.stops[0], g.stops[len(g.stops)-1]
+	if t <= first.offset {
+		return colorFromUnit(first.color)
+	}
+	if t >= last.offset {
+		return colorFromUnit(last.color)
+	}
+
+	for i := 1; i < len(g.stops); i++ {
+		b := g.stops[i]
+		if t > b.offset {
+			continue
+		}
+		a := g.stops[i-1]
+		local := 0.0
+		if span := b.offset - a.offset; span > 0 {
+			local = (t - a.offset) / span
+		}
+		return lerpRGB(a.color, b.color, local)
+	}
+
+	return colorFromUnit(last.color)
+}
+
+func colorFromUnit(c [4]float64) color.RGBA {
+	return color.RGBA{uint8(c[0] * 255), uint8(c[1] * 255), uint8(c[2] * 255), uint8(c[3] * 255)}
+}
+
+// BuildGradientPalette converts a client-supplied Gradient into a Palette,
+// sorting stops by offset and validating the hex colors.
+func BuildGradientPalette(g Gradient) (Palette, error) {
+	if len(g.Stops) < 2 {
+		return nil, fmt.Errorf("palette: gradient needs at least 2 stops, got %d", len(g.Stops))
+	}
+
+	stops := make([]gradientStop, 0, len(g.Stops))
+	for _, s := range g.Stops {
+		c, err := parseHexColor(s.Color)
+		if err != nil {
+			return nil, err
+		}
+		stops = append(stops, gradientStop{offset: clamp01(s.Offset), color: c})
+	}
+
+	sort.Slice(stops, func(i, j int) bool { return stops[i].offset < stops[j].offset })
+
+	return gradientPalette{stops: stops}, nil
+}
+
+func parseHexColor(s string) ([4]float64, error) {
+	s = strings.TrimPrefix(s, "#")
+
+	var r, g, b uint8
+	a := uint8(255)
+
+	switch len(s) {
+	case 6:
+		if _, err := fmt.Sscanf(s, "%02x%02x%02x", &r, &g, &b); err != nil {
+			return [4]float64{}, fmt.Errorf("palette: invalid color %q: %w", s, err)
+		}
+	case 8:
+		if _, err := fmt.Sscanf(s, "%02x%02x%02x%02x", &r, &g, &b, &a); err != nil {
+			return [4]float64{}, fmt.Errorf("palette: invalid color %q: %w", s, err)
+		}
+	default:
+		return [4]float64{}, fmt.Errorf("palette: invalid color %q", s)
+	}
+
+	return [4]float64{float64(r) / 255, float64(g) / 255, float64(b) / 255, float64(a) / 255}, nil
+}
@@ -0,0 +1,75 @@
+package palette
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestBuildGradientPaletteTooFewStops(t *testing.T) {
+	_, err := BuildGradientPalette(Gradient{Stops: []GradientStop{{Offset: 0, Color: "#000000"}}})
+	if err == nil {
+		t.Fatal("expected an error for a gradient with fewer than 2 stops")
+	}
+}
+
+func TestBuildGradientPaletteInvalidColor(t *testing.T) {
+	_, err := BuildGradientPalette(Gradient{Stops: []GradientStop{
+		{Offset: 0, Color: "#0000"},
+		{Offset: 1, Color: "#ffffff"},
+	}})
+	if err == nil {
+		t.Fatal("expected an error for a malformed hex color")
+	}
+}
+
+func TestBuildGradientPaletteSortsUnorderedStops(t *testing.T) {
+	p, err := BuildGradientPalette(Gradient{Stops: []GradientStop{
+		{Offset: 1, Color: "#ffffff"},
+		{Offset: 0, Color: "#000000"},
+	}})
+	if err != nil {
+		t.Fatalf("BuildGradientPalette: %v", err)
+	}
+
+	got := p.ColorAt(0)
+	want := color.RGBA{0, 0, 0, 255}
+	if got != want {
+		t.Errorf("ColorAt(0) = %+v, want %+v (stops should be sorted by offset)", got, want)
+	}
+}
+
+func TestGradientPaletteColorAtClampsOutOfRange(t *testing.T) {
+	p, err := BuildGradientPalette(Gradient{Stops: []GradientStop{
+		{Offset: 0.25, Color: "#000000"},
+		{Offset: 0.75, Color: "#ffffff"},
+	}})
+	if err != nil {
+		t.Fatalf("BuildGradientPalette: %v", err)
+	}
+
+	if got, want := p.ColorAt(-1), (color.RGBA{0, 0, 0, 255}); got != want {
+		t.Errorf("ColorAt(-1) = %+v, want %+v (clamp to the first stop)", got, want)
+	}
+	if got, want := p.ColorAt(2), (color.RGBA{255, 255, 255, 255}); got != want {
+		t.Errorf("ColorAt(2) = %+v, want %+v (clamp to the last stop)", got, want)
+	}
+}
+
+func TestParseHexColorWithAlpha(t *testing.T) {
+	c, err := parseHexColor("#80402010")
+	if err != nil {
+		t.Fatalf("parseHexColor: %v", err)
+	}
+	want := [4]float64{float64(0x80) / 255, float64(0x40) / 255, float64(0x20) / 255, float64(0x10) / 255}
+	if c != want {
+		t.Errorf("parseHexColor(#80402010) = %v, want %v", c, want)
+	}
+}
+
+func TestParseHexColorInvalid(t *testing.T) {
+	for _, s := range []string{"", "#fff", "#gggggg", "#ffffffffff"} {
+		if _, err := parseHexColor(s); err == nil {
+			t.Errorf("parseHexColor(%q): expected an error", s)
+		}
+	}
+}
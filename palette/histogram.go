@@ -0,0 +1,34 @@
+package palette
+
+// HistogramEqualize buckets escape-iteration counts across a frame (or
+// tile) and returns, for each possible iteration count 0..maxIterations,
+// its position in the resulting cumulative distribution. Coloring a pixel
+// at cdf[i] instead of i/maxIterations spreads the palette evenly across
+// whatever iteration counts actually occur, rather than assuming they're
+// uniform - the gaps where no pixel escaped get squeezed out.
+func HistogramEqualize(counts []int, maxIterations int) []float64 {
+	histogram := make([]int, maxIterations+1)
+	total := 0
+	for _, c := range counts {
+		if c < 0 {
+			c = 0
+		} else if c > maxIterations {
+			c = maxIterations
+		}
+		histogram[c]++
+		total++
+	}
+
+	cdf := make([]float64, maxIterations+1)
+	if total == 0 {
+		return cdf
+	}
+
+	running := 0
+	for i, count := range histogram {
+		running += count
+		cdf[i] = float64(running) / float64(total)
+	}
+
+	return cdf
+}
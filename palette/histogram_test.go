@@ -0,0 +1,40 @@
+package palette
+
+import "testing"
+
+func TestHistogramEqualizeEmpty(t *testing.T) {
+	cdf := HistogramEqualize(nil, 10)
+	if len(cdf) != 11 {
+		t.Fatalf("len(cdf) = %d, want 11", len(cdf))
+	}
+	for i, v := range cdf {
+		if v != 0 {
+			t.Errorf("cdf[%d] = %v, want 0 for an empty frame", i, v)
+		}
+	}
+}
+
+func TestHistogramEqualizeMonotonic(t *testing.T) {
+	cdf := HistogramEqualize([]int{0, 2, 2, 5, 5, 5, 10}, 10)
+	for i := 1; i < len(cdf); i++ {
+		if cdf[i] < cdf[i-1] {
+			t.Fatalf("cdf[%d]=%v < cdf[%d]=%v, cdf must be non-decreasing", i, cdf[i], i-1, cdf[i-1])
+		}
+	}
+	if cdf[10] != 1 {
+		t.Errorf("cdf[maxIterations] = %v, want 1", cdf[10])
+	}
+	if cdf[0] != 1.0/7 {
+		t.Errorf("cdf[0] = %v, want %v", cdf[0], 1.0/7)
+	}
+}
+
+func TestHistogramEqualizeClampsOutOfRange(t *testing.T) {
+	cdf := HistogramEqualize([]int{-5, 50}, 10)
+	if cdf[0] != 0.5 {
+		t.Errorf("cdf[0] = %v, want 0.5 (the out-of-range -5 clamped to 0)", cdf[0])
+	}
+	if cdf[10] != 1 {
+		t.Errorf("cdf[10] = %v, want 1 (the out-of-range 50 clamped to 10)", cdf[10])
+	}
+}
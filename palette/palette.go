@@ -0,0 +1,221 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Palette maps a normalized escape value t (0 at the first iteration,
+// approaching 1 near MaxIterations without escaping) to a color. Callers
+// are responsible for coloring pixels that never escape separately -
+// solid black by convention - since that's a rendering choice, not
+// something a palette needs to know about.
+type Palette interface {
+	ColorAt(t float64) color.RGBA
+}
+
+// PaletteFunc adapts a plain func(t float64) color.RGBA to Palette.
+type PaletteFunc func(t float64) color.RGBA
+
+func (f PaletteFunc) ColorAt(t float64) color.RGBA { return f(t) }
+
+var (
+	paletteMu  sync.RWMutex
+	paletteReg = map[string]Palette{}
+)
+
+// RegisterPalette adds or replaces a named palette. Built-ins register
+// themselves below at init time; the .ggr/.gpl loaders and the JSON
+// gradient format register whatever a client imports at runtime.
+func RegisterPalette(name string, p Palette) {
+	paletteMu.Lock()
+	defer paletteMu.Unlock()
+	paletteReg[name] = p
+}
+
+// GetPalette looks up a palette by name.
+func GetPalette(name string) (Palette, bool) {
+	paletteMu.RLock()
+	defer paletteMu.RUnlock()
+	p, ok := paletteReg[name]
+	return p, ok
+}
+
+// PaletteNames returns every registered palette name, sorted.
+func PaletteNames() []string {
+	paletteMu.RLock()
+	defer paletteMu.RUnlock()
+	names := make([]string, 0, len(paletteReg))
+	for name := range paletteReg {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func clamp01(t float64) float64 {
+	return math.Min(1, math.Max(0, t))
+}
+
+func init() {
+	RegisterPalette("smooth-hsv", PaletteFunc(smoothHSVPalette))
+	RegisterPalette("stripe", PaletteFunc(stripePalette))
+	RegisterPalette("electric-plasma", PaletteFunc(electricPlasmaPalette))
+	RegisterPalette("psychedelic-swirl", PaletteFunc(psychedelicSwirlPalette))
+	RegisterPalette("metallic-sheen", PaletteFunc(metallicSheenPalette))
+	RegisterPalette("rainbow-spiral", PaletteFunc(rainbowSpiralPalette))
+	RegisterPalette("autumn-leaves", PaletteFunc(autumnLeavesPalette))
+	RegisterPalette("ocean-depths", PaletteFunc(oceanDepthsPalette))
+	RegisterPalette("molten-lava", PaletteFunc(moltenLavaPalette))
+	RegisterPalette("grey", PaletteFunc(greyPalette))
+	RegisterPalette("blend-smooth-metallic", blendPalette{
+		a: PaletteFunc(smoothHSVPalette),
+		b: PaletteFunc(metallicSheenPalette),
+	})
+	RegisterPalette("mix-stripe-metallic-ocean", mixPalette{
+		p: [3]Palette{
+			PaletteFunc(stripePalette),
+			PaletteFunc(metallicSheenPalette),
+			PaletteFunc(oceanDepthsPalette),
+		},
+	})
+}
+
+func smoothHSVPalette(t float64) color.RGBA {
+	hue := math.Sin(t * 2 * math.Pi * 3)
+	c := colorful.Hsv(hue*360, 0.8, 1.0)
+	r, g, b := c.RGB255()
+	return color.RGBA{r, g, b, 255}
+}
+
+func stripePalette(t float64) color.RGBA {
+	const bands = 24.0
+	band := math.Mod(t*bands, 1.0)
+	hue := math.Mod(t*bands, 6) / 6.0 * 360
+	saturation := 0.8 + 0.2*math.Sin(t*bands*2*math.Pi)
+	value := 1.0 - 0.5*band
+	c := colorful.Hsv(hue, saturation, value)
+	r, g, b := c.RGB255()
+	return color.RGBA{r, g, b, 255}
+}
+
+func electricPlasmaPalette(t float64) color.RGBA {
+	r := uint8(math.Sin(t*math.Pi)*127 + 128)
+	g := uint8(math.Sin(t*math.Pi*2)*127 + 128)
+	b := uint8(math.Sin(t*math.Pi*4)*127 + 128)
+	return color.RGBA{r, g, b, 255}
+}
+
+func psychedelicSwirlPalette(t float64) color.RGBA {
+	angle := t * 4 * math.Pi
+	hue := math.Mod(angle/(2*math.Pi), 1.0) * 360
+	saturation := 0.8 + 0.2*math.Sin(t*10)
+	value := 1.0 - math.Pow(t, 0.3)
+	c := colorful.Hsv(hue, saturation, value)
+	r, g, b := c.RGB255()
+	return color.RGBA{r, g, b, 255}
+}
+
+func metallicSheenPalette(t float64) color.RGBA {
+	r := uint8(128 + 127*math.Sin(t*2*math.Pi))
+	g := uint8(128 + 127*math.Sin(t*2*math.Pi+2*math.Pi/3))
+	b := uint8(128 + 127*math.Sin(t*2*math.Pi+4*math.Pi/3))
+	return color.RGBA{r, g, b, 255}
+}
+
+func rainbowSpiralPalette(t float64) color.RGBA {
+	base := colorful.Hsv(t*360, 1.0, 1.0)
+	r, g, b := base.RGB255()
+	intensity := uint8(255 * math.Pow(1.0-t, 3))
+	return color.RGBA{r, g, b, intensity}
+}
+
+func autumnLeavesPalette(t float64) color.RGBA {
+	hue := 30 + 60*math.Sin(t*math.Pi)
+	saturation := 0.8 + 0.2*math.Cos(t*2*math.Pi)
+	value := 0.7 + 0.3*math.Sin(t*4*math.Pi)
+	c := colorful.Hsv(hue, saturation, value)
+	r, g, b := c.RGB255()
+	return color.RGBA{r, g, b, 255}
+}
+
+func oceanDepthsPalette(t float64) color.RGBA {
+	hue := 180 + 60*math.Sin(t*math.Pi)
+	saturation := 0.7 + 0.3*math.Cos(t*2*math.Pi)
+	value := 0.5 + 0.5*math.Pow(t, 0.5)
+	c := colorful.Hsv(hue, saturation, value)
+	r, g, b := c.RGB255()
+	return color.RGBA{r, g, b, 255}
+}
+
+func moltenLavaPalette(t float64) color.RGBA {
+	r := uint8(255 * math.Pow(t, 0.5))
+	g := uint8(128 * math.Pow(t, 2))
+	b := uint8(64 * math.Pow(t, 4))
+	return color.RGBA{r, g, b, 255}
+}
+
+var greyTable = buildGreyTable()
+
+func buildGreyTable() [256]uint8 {
+	var table [256]uint8
+	for i := 0; i < 256; i++ {
+		v := i + 512 - 512*int(math.Exp(-float64(i)/50))/3
+		table[i] = uint8(v)
+	}
+	return table
+}
+
+func greyPalette(t float64) color.RGBA {
+	v := greyTable[int(clamp01(t)*255)]
+	return color.RGBA{v, v, v, 255}
+}
+
+// blendPalette cross-fades between two palettes, weighted by sin(t*pi) so
+// the blend leans on `a` in the middle of the range and `b` at the ends.
+type blendPalette struct {
+	a, b Palette
+}
+
+func (p blendPalette) ColorAt(t float64) color.RGBA {
+	c1 := p.a.ColorAt(t)
+	c2 := p.b.ColorAt(t)
+	blendFactor := math.Sin(t * math.Pi)
+	return color.RGBA{
+		R: uint8(float64(c1.R)*blendFactor + float64(c2.R)*(1-blendFactor)),
+		G: uint8(float64(c1.G)*blendFactor + float64(c2.G)*(1-blendFactor)),
+		B: uint8(float64(c1.B)*blendFactor + float64(c2.B)*(1-blendFactor)),
+		A: 255,
+	}
+}
+
+// mixPalette combines three palettes with offset sine weights, so each
+// dominates a different stretch of t.
+type mixPalette struct {
+	p [3]Palette
+}
+
+func (m mixPalette) ColorAt(t float64) color.RGBA {
+	c1 := m.p[0].ColorAt(t)
+	c2 := m.p[1].ColorAt(t)
+	c3 := m.p[2].ColorAt(t)
+
+	w1 := math.Sin(t * math.Pi)
+	w2 := math.Sin(t * 2 * math.Pi)
+	w3 := math.Sin(t * 4 * math.Pi)
+	total := w1 + w2 + w3
+	if total == 0 {
+		total = 1
+	}
+
+	return color.RGBA{
+		R: uint8((float64(c1.R)*w1 + float64(c2.R)*w2 + float64(c3.R)*w3) / total),
+		G: uint8((float64(c1.G)*w1 + float64(c2.G)*w2 + float64(c3.G)*w3) / total),
+		B: uint8((float64(c1.B)*w1 + float64(c2.B)*w2 + float64(c3.B)*w3) / total),
+		A: 255,
+	}
+}
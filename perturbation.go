@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"math/cmplx"
+)
+
+// perturbationPrecisionBits is the working precision for the reference
+// orbit. 106 bits is roughly double a float64's 53-bit mantissa, enough
+// headroom for the zoom depths this renderer targets before a true
+// arbitrary-precision reference becomes necessary.
+const perturbationPrecisionBits = 106
+
+// referenceOrbit is one pixel's orbit, iterated in high precision and then
+// downcast to complex128 per step so the delta recurrence for every other
+// pixel can run in ordinary float64 complex arithmetic.
+type referenceOrbit struct {
+	z         []complex128
+	escapedAt int // index where |z| first exceeded 2; len(z) if it never did
+}
+
+// computeReferenceOrbit iterates the center pixel (the perturbation
+// reference) in big.Float precision: z0 is the center and c is
+// params.C, same as computeJuliaTile's per-pixel loop - this renderer
+// only ever draws Julia-style iteration, regardless of whether C happens
+// to be zero.
+func computeReferenceOrbit(centerReal, centerImag *big.Float, c complex128, maxIterations int) referenceOrbit {
+	prec := uint(perturbationPrecisionBits)
+
+	zr := new(big.Float).SetPrec(prec)
+	zi := new(big.Float).SetPrec(prec)
+	cr := new(big.Float).SetPrec(prec)
+	ci := new(big.Float).SetPrec(prec)
+
+	zr.Set(centerReal)
+	zi.Set(centerImag)
+	cr.SetFloat64(real(c))
+	ci.SetFloat64(imag(c))
+
+	orbit := referenceOrbit{z: make([]complex128, 0, maxIterations)}
+
+	tr := new(big.Float).SetPrec(prec)
+	ti := new(big.Float).SetPrec(prec)
+	sq := new(big.Float).SetPrec(prec)
+	two := big.NewFloat(2)
+
+	for n := 0; n < maxIterations; n++ {
+		zrF, _ := zr.Float64()
+		ziF, _ := zi.Float64()
+		orbit.z = append(orbit.z, complex(zrF, ziF))
+
+		if zrF*zrF+ziF*ziF > 4 {
+			orbit.escapedAt = n
+			return orbit
+		}
+
+		// tr = zr*zr - zi*zi + cr
+		sq.Mul(zi, zi)
+		tr.Mul(zr, zr)
+		tr.Sub(tr, sq)
+		tr.Add(tr, cr)
+
+		// ti = 2*zr*zi + ci
+		ti.Mul(zr, zi)
+		ti.Mul(ti, two)
+		ti.Add(ti, ci)
+
+		zr.Set(tr)
+		zi.Set(ti)
+	}
+
+	orbit.escapedAt = maxIterations
+	return orbit
+}
+
+// seriesApproximationWarmup fits dz_n ≈ A_n*dc + B_n*dc^2 + C_n*dc^3 along
+// the reference orbit (A_0 = B_0 = C_0 = 0), so every pixel's delta orbit
+// can start at iteration `warmup` instead of 0. The fit is advanced until a
+// coefficient blows up, which signals the cubic approximation can no longer
+// be trusted, or a fixed ceiling is hit.
+func seriesApproximationWarmup(orbit referenceOrbit) (a, b, c []complex128, warmup int) {
+	n := orbit.escapedAt
+	if n > len(orbit.z) {
+		n = len(orbit.z)
+	}
+	if n < 1 {
+		return nil, nil, nil, 0
+	}
+
+	a = make([]complex128, n)
+	b = make([]complex128, n)
+	c = make([]complex128, n)
+
+	const maxWarmup = 256
+	const blowupThreshold = 1e6
+
+	for i := 0; i < n-1; i++ {
+		Zn := orbit.z[i]
+		a[i+1] = 2*Zn*a[i] + 1
+		b[i+1] = 2*Zn*b[i] + a[i]*a[i]
+		c[i+1] = 2*Zn*c[i] + 2*a[i]*b[i]
+
+		if cmplx.Abs(a[i+1]) > blowupThreshold || cmplx.Abs(b[i+1]) > blowupThreshold || cmplx.Abs(c[i+1]) > blowupThreshold {
+			break
+		}
+
+		warmup = i + 1
+		if warmup >= maxWarmup {
+			break
+		}
+	}
+
+	return a, b, c, warmup
+}
+
+// iterateDeltaOrbit walks the delta orbit dz_{n+1} = 2*Z_n*dz_n + dz_n^2 +
+// dc starting from the series-approximated value at iteration `warmup`,
+// escaping when |Z_n + dz_n| > 2. If the reference orbit escapes first, or
+// a glitch is detected (|Z_n + dz_n| drops below |dz_n|, meaning dz no
+// longer tracks a small correction), the pixel is rebased: the current
+// Z_n + dz_n is treated as an independent orbit and finished with the
+// ordinary float64 recurrence, approximating a fresh local reference.
+func iterateDeltaOrbit(orbit referenceOrbit, a, b, c []complex128, warmup int, dc complex128, maxIterations int) (int, complex128) {
+	if warmup >= len(orbit.z) {
+		warmup = len(orbit.z) - 1
+	}
+	if warmup < 0 {
+		return iterateDirect(complex(0, 0), dc, 0, maxIterations)
+	}
+
+	var dz complex128
+	if warmup < len(a) {
+		dz = a[warmup]*dc + b[warmup]*dc*dc + c[warmup]*dc*dc*dc
+	}
+
+	for n := warmup; n < maxIterations; n++ {
+		if n >= orbit.escapedAt {
+			return iterateDirect(orbit.z[len(orbit.z)-1]+dz, dc, n, maxIterations)
+		}
+
+		Zn := orbit.z[n]
+		full := Zn + dz
+		if cmplx.Abs(full) > 2 {
+			return n, full
+		}
+
+		if cmplx.Abs(full) < cmplx.Abs(dz) {
+			return iterateDirect(full, dc, n, maxIterations)
+		}
+
+		dz = 2*Zn*dz + dz*dz + dc
+	}
+
+	return maxIterations, orbit.z[len(orbit.z)-1] + dz
+}
+
+// iterateDirect continues a pixel's orbit with ordinary float64 complex
+// arithmetic, used as the glitch/escape fallback above.
+func iterateDirect(z, dc complex128, startN, maxIterations int) (int, complex128) {
+	i := startN
+	for ; i < maxIterations; i++ {
+		if cmplx.Abs(z) > 2 {
+			break
+		}
+		z = z*z + dc
+	}
+	return i, z
+}
+
+// computePerturbationTile runs the delta-orbit recurrence over tile's
+// pixels using perturbation theory, stopping short of coloring the
+// result. See calculatePerturbationTile for the single-tile caller and
+// renderHistogramFrame (main.go) for the frame-wide one.
+func computePerturbationTile(params JuliaParams, tile Tile, ctx context.Context) (iters []int, zs []complex128, ok bool) {
+	c := complex(params.C.Real, params.C.Imag)
+
+	prec := uint(perturbationPrecisionBits)
+	centerReal := new(big.Float).SetPrec(prec).SetFloat64(params.Center.Real)
+	centerImag := new(big.Float).SetPrec(prec).SetFloat64(params.Center.Imag)
+
+	orbit := computeReferenceOrbit(centerReal, centerImag, c, params.MaxIterations)
+	a, b, cc, warmup := seriesApproximationWarmup(orbit)
+
+	iters = make([]int, tile.W*tile.H)
+	zs = make([]complex128, tile.W*tile.H)
+
+	for ty := 0; ty < tile.H; ty++ {
+		if ty%16 == 0 {
+			select {
+			case <-ctx.Done():
+				return nil, nil, false
+			default:
+			}
+		}
+
+		y := tile.Y + ty
+		offsetImag := float64(y)/params.Zoom - float64(params.Height)/(2*params.Zoom)
+		for tx := 0; tx < tile.W; tx++ {
+			x := tile.X + tx
+			offsetReal := float64(x)/params.Zoom - float64(params.Width)/(2*params.Zoom)
+
+			// dc is the pixel's offset from the reference orbit, computed
+			// directly rather than by adding Center and subtracting it back
+			// off: at the zoom depths this renderer targets, Center's
+			// magnitude swamps the offset in float64 and rounds dc to 0.
+			dc := complex(offsetReal, offsetImag)
+			i, z := iterateDeltaOrbit(orbit, a, b, cc, warmup, dc, params.MaxIterations)
+
+			idx := ty*tile.W + tx
+			iters[idx] = i
+			zs[idx] = z
+		}
+	}
+
+	return iters, zs, true
+}
+
+// calculatePerturbationTile renders tile using perturbation theory: one
+// reference pixel is iterated in big.Float precision, and every other
+// pixel tracks only its delta from that orbit in complex128 arithmetic.
+// Unlike calculateJuliaTile, this stays accurate past the point where
+// params.Zoom exceeds what float64 can represent (roughly >1e13), where the
+// float64 path degrades into banding.
+func calculatePerturbationTile(params JuliaParams, tile Tile, ctx context.Context) (data []byte, ok bool) {
+	iters, zs, ok := computePerturbationTile(params, tile, ctx)
+	if !ok {
+		return nil, false
+	}
+	return colorTile(params, iters, zs, nil), true
+}
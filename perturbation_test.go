@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"testing"
+)
+
+func TestSeriesApproximationWarmupEmptyOrbit(t *testing.T) {
+	orbit := referenceOrbit{z: nil, escapedAt: 0}
+	a, b, c, warmup := seriesApproximationWarmup(orbit)
+	if a != nil || b != nil || c != nil || warmup != 0 {
+		t.Fatalf("got (%v, %v, %v, %d), want all nil/0 for an empty orbit", a, b, c, warmup)
+	}
+}
+
+func TestSeriesApproximationWarmupMatchesRecurrence(t *testing.T) {
+	orbit := referenceOrbit{
+		z:         []complex128{0, 0.1 + 0.1i, 0.12 + 0.21i, 0.0941 + 0.2504i},
+		escapedAt: 4,
+	}
+	a, b, c, warmup := seriesApproximationWarmup(orbit)
+
+	if warmup != len(orbit.z)-1 {
+		t.Fatalf("warmup = %d, want %d (no coefficient should blow up for this tame orbit)", warmup, len(orbit.z)-1)
+	}
+	if a[0] != 0 || b[0] != 0 || c[0] != 0 {
+		t.Fatalf("a[0]/b[0]/c[0] = %v/%v/%v, want 0/0/0", a[0], b[0], c[0])
+	}
+
+	for i := 0; i < len(orbit.z)-1; i++ {
+		Zn := orbit.z[i]
+		wantA := 2*Zn*a[i] + 1
+		wantB := 2*Zn*b[i] + a[i]*a[i]
+		wantC := 2*Zn*c[i] + 2*a[i]*b[i]
+		if a[i+1] != wantA || b[i+1] != wantB || c[i+1] != wantC {
+			t.Fatalf("step %d: got a=%v b=%v c=%v, want a=%v b=%v c=%v", i, a[i+1], b[i+1], c[i+1], wantA, wantB, wantC)
+		}
+	}
+}
+
+func TestSeriesApproximationWarmupStopsOnBlowup(t *testing.T) {
+	orbit := referenceOrbit{
+		z:         []complex128{1000, 1000, 1000, 1000},
+		escapedAt: 4,
+	}
+	_, _, _, warmup := seriesApproximationWarmup(orbit)
+	if warmup >= len(orbit.z)-1 {
+		t.Fatalf("warmup = %d, want it to stop early once a coefficient exceeds the blowup threshold", warmup)
+	}
+}
+
+func TestIterateDeltaOrbitNoGlitchTracksReference(t *testing.T) {
+	maxIterations := 50
+	centerReal := new(big.Float).SetPrec(perturbationPrecisionBits).SetFloat64(0)
+	centerImag := new(big.Float).SetPrec(perturbationPrecisionBits).SetFloat64(0)
+	c := complex(-0.5, 0)
+
+	orbit := computeReferenceOrbit(centerReal, centerImag, c, maxIterations)
+	a, b, cc, warmup := seriesApproximationWarmup(orbit)
+
+	dc := complex(0.0, 0.0)
+	gotI, gotZ := iterateDeltaOrbit(orbit, a, b, cc, warmup, dc, maxIterations)
+
+	if gotI != orbit.escapedAt {
+		t.Errorf("i = %d, want escapedAt = %d for dc=0 (same point as the reference)", gotI, orbit.escapedAt)
+	}
+	if orbit.escapedAt == maxIterations {
+		wantZ := orbit.z[len(orbit.z)-1]
+		if cmplx.Abs(gotZ-wantZ) > 1e-9 {
+			t.Errorf("z = %v, want %v", gotZ, wantZ)
+		}
+	}
+}
+
+func TestIterateDeltaOrbitRebasesWhenReferenceEscapes(t *testing.T) {
+	// A reference orbit that escapes immediately forces iterateDeltaOrbit
+	// into its n >= escapedAt branch, which rebases onto iterateDirect.
+	orbit := referenceOrbit{z: []complex128{3 + 0i}, escapedAt: 0}
+	i, z := iterateDeltaOrbit(orbit, nil, nil, nil, 0, complex(0, 0), 10)
+	wantI, wantZ := iterateDirect(orbit.z[0], complex(0, 0), 0, 10)
+	if i != wantI || z != wantZ {
+		t.Errorf("iterateDeltaOrbit = (%d, %v), want iterateDirect's (%d, %v)", i, z, wantI, wantZ)
+	}
+}
+
+func TestIterateDirectCountsEscapeIteration(t *testing.T) {
+	// z0=0, dc=2: z1 = 0^2+2 = 2 (|z1|=2, not yet > 2), z2 = 2^2+2 = 6
+	// (|z2| > 2, escapes). iterateDirect should report i=2.
+	i, z := iterateDirect(complex(0, 0), complex(2, 0), 0, 10)
+	if i != 2 {
+		t.Errorf("i = %d, want 2", i)
+	}
+	if cmplx.Abs(z) <= 2 {
+		t.Errorf("z = %v, want |z| > 2 at escape", z)
+	}
+}
+
+func TestComputeReferenceOrbitIsJuliaStyle(t *testing.T) {
+	// computeReferenceOrbit must behave like computeJuliaTile: z0 is the
+	// center pixel, c is params.C - even when C is exactly zero, this
+	// must not become Mandelbrot-style iteration (z0=0, c=pixel).
+	prec := uint(perturbationPrecisionBits)
+	centerReal := new(big.Float).SetPrec(prec).SetFloat64(0.5)
+	centerImag := new(big.Float).SetPrec(prec).SetFloat64(0)
+	c := complex(0, 0)
+
+	orbit := computeReferenceOrbit(centerReal, centerImag, c, 5)
+	if len(orbit.z) == 0 {
+		t.Fatal("expected a non-empty orbit")
+	}
+	if orbit.z[0] != complex(0.5, 0) {
+		t.Errorf("orbit.z[0] = %v, want (0.5, 0) (the center pixel, regardless of C)", orbit.z[0])
+	}
+
+	// z_1 = z_0^2 + c = 0.25 + 0 = 0.25, matching Julia-style iteration for c=0.
+	if math.Abs(real(orbit.z[1])-0.25) > 1e-9 || imag(orbit.z[1]) != 0 {
+		t.Errorf("orbit.z[1] = %v, want (0.25, 0)", orbit.z[1])
+	}
+}
+
+func TestComputePerturbationTileRespectsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	params := JuliaParams{
+		Width: 16, Height: 16, Zoom: 4, MaxIterations: 100,
+		Center: Complex{Real: 0, Imag: 0},
+		C:      Complex{Real: 0, Imag: 0},
+	}
+	tile := Tile{X: 0, Y: 0, W: 16, H: 16}
+
+	_, _, ok := computePerturbationTile(params, tile, ctx)
+	if ok {
+		t.Error("expected computePerturbationTile to report !ok once its context is already cancelled")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+)
+
+// Renderer computes one tile's escape-time result, returning ok=false if
+// ctx is cancelled before it finishes - the same contract
+// calculateJuliaTile and calculatePerturbationTile already have.
+// tileWorker calls activeRenderer instead of either function directly, so
+// the escape-time loop can move off the CPU without touching the tile
+// queue, cancellation, or palette code around it.
+type Renderer interface {
+	RenderTile(params JuliaParams, tile Tile, ctx context.Context) (pixels []byte, ok bool)
+	// ComputeTile runs the same escape-time work as RenderTile but stops
+	// short of coloring it, so a caller can pool iteration counts across
+	// tiles before choosing how to color them - see renderHistogramFrame
+	// in main.go.
+	ComputeTile(params JuliaParams, tile Tile, ctx context.Context) (iters []int, zs []complex128, ok bool)
+}
+
+// cpuRenderer is the original goroutine-parallelized implementation.
+type cpuRenderer struct{}
+
+func (cpuRenderer) RenderTile(params JuliaParams, tile Tile, ctx context.Context) ([]byte, bool) {
+	if params.Precision == "perturbation" {
+		return calculatePerturbationTile(params, tile, ctx)
+	}
+	return calculateJuliaTile(params, tile, ctx)
+}
+
+func (cpuRenderer) ComputeTile(params JuliaParams, tile Tile, ctx context.Context) ([]int, []complex128, bool) {
+	if params.Precision == "perturbation" {
+		return computePerturbationTile(params, tile, ctx)
+	}
+	return computeJuliaTile(params, tile, ctx)
+}
+
+// activeRenderer is selected once at startup by selectRenderer and read by
+// tileWorker for every tile.
+var activeRenderer Renderer = cpuRenderer{}
+
+// selectRenderer reads FRACTAL_BACKEND ("cpu" or "gpu"; default "cpu") and
+// swaps in the GPU renderer if requested and available. newGPURenderer is
+// provided by renderer_gpu.go under the `gpu` build tag, or by
+// renderer_gpu_stub.go in a default build where no GPU backend is
+// compiled in; either way, any failure to get a working adapter falls
+// back to the CPU renderer rather than refusing to start.
+func selectRenderer() {
+	backend := os.Getenv("FRACTAL_BACKEND")
+	if backend == "" {
+		backend = "cpu"
+	}
+
+	if backend != "gpu" {
+		if backend != "cpu" {
+			log.Printf("Unknown FRACTAL_BACKEND %q, using cpu", backend)
+		}
+		activeRenderer = cpuRenderer{}
+		return
+	}
+
+	gpu, err := newGPURenderer()
+	if err != nil {
+		log.Printf("GPU backend requested but unavailable (%v), falling back to cpu", err)
+		activeRenderer = cpuRenderer{}
+		return
+	}
+
+	activeRenderer = gpu
+}
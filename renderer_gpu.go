@@ -0,0 +1,309 @@
+//go:build gpu
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	webgpu "github.com/rajveermalviya/go-webgpu/wgpu"
+)
+
+// escapeTimeShader is the compute-shader half of the pipeline: one
+// work-item per pixel, writing the iteration count and final z to two
+// storage buffers. The coloring step stays on the CPU (see
+// gpuRenderer.RenderTile), so palettes - including ones imported or
+// posted at runtime - keep working unchanged.
+const escapeTimeShader = `
+struct Params {
+  width: u32,
+  height: u32,
+  maxIterations: u32,
+  tileX: u32,
+  tileY: u32,
+  tileW: u32,
+  tileH: u32,
+  zoom: f32,
+  centerReal: f32,
+  centerImag: f32,
+  cReal: f32,
+  cImag: f32,
+}
+
+@group(0) @binding(0) var<uniform> params: Params;
+@group(0) @binding(1) var<storage, read_write> iterations: array<u32>;
+@group(0) @binding(2) var<storage, read_write> finalZ: array<vec2<f32>>;
+
+@compute @workgroup_size(8, 8, 1)
+fn main(@builtin(global_invocation_id) id: vec3<u32>) {
+  if (id.x >= params.tileW || id.y >= params.tileH) {
+    return;
+  }
+
+  let px = f32(params.tileX + id.x);
+  let py = f32(params.tileY + id.y);
+
+  let real0 = px / params.zoom - f32(params.width) / (2.0 * params.zoom) + params.centerReal;
+  let imag0 = py / params.zoom - f32(params.height) / (2.0 * params.zoom) + params.centerImag;
+
+  var zr = real0;
+  var zi = imag0;
+  var i: u32 = 0u;
+
+  loop {
+    if (i >= params.maxIterations || zr * zr + zi * zi > 4.0) {
+      break;
+    }
+    let nr = zr * zr - zi * zi + params.cReal;
+    let ni = 2.0 * zr * zi + params.cImag;
+    zr = nr;
+    zi = ni;
+    i = i + 1u;
+  }
+
+  let idx = id.y * params.tileW + id.x;
+  iterations[idx] = i;
+  finalZ[idx] = vec2<f32>(zr, zi);
+}
+`
+
+// gpuRenderer dispatches the escape-time loop to a WebGPU compute
+// pipeline, one work-item per pixel, and reads back the iteration count
+// and final z as two buffers.
+type gpuRenderer struct {
+	device   *webgpu.Device
+	queue    *webgpu.Queue
+	pipeline *webgpu.ComputePipeline
+}
+
+func newGPURenderer() (Renderer, error) {
+	instance := webgpu.CreateInstance(nil)
+
+	adapter, err := instance.RequestAdapter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("gpu: no adapter available: %w", err)
+	}
+
+	device, err := adapter.RequestDevice(nil)
+	if err != nil {
+		return nil, fmt.Errorf("gpu: could not create device: %w", err)
+	}
+
+	module, err := device.CreateShaderModule(&webgpu.ShaderModuleDescriptor{
+		WGSLDescriptor: &webgpu.ShaderModuleWGSLDescriptor{Code: escapeTimeShader},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gpu: could not compile shader: %w", err)
+	}
+
+	pipeline, err := device.CreateComputePipeline(&webgpu.ComputePipelineDescriptor{
+		Compute: webgpu.ProgrammableStageDescriptor{Module: module, EntryPoint: "main"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gpu: could not create pipeline: %w", err)
+	}
+
+	return &gpuRenderer{device: device, queue: device.GetQueue(), pipeline: pipeline}, nil
+}
+
+func (r *gpuRenderer) RenderTile(params JuliaParams, tile Tile, ctx context.Context) ([]byte, bool) {
+	iters, zs, ok := r.ComputeTile(params, tile, ctx)
+	if !ok {
+		return nil, false
+	}
+	return colorTile(params, iters, zs, nil), true
+}
+
+// ComputeTile runs dispatch but stops short of coloring the result - see
+// renderHistogramFrame in main.go, which pools iters across every tile in
+// a frame before any of them are colored.
+func (r *gpuRenderer) ComputeTile(params JuliaParams, tile Tile, ctx context.Context) ([]int, []complex128, bool) {
+	select {
+	case <-ctx.Done():
+		return nil, nil, false
+	default:
+	}
+
+	iters, zs, err := r.dispatch(params, tile)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, nil, false
+	default:
+	}
+
+	return iters, zs, true
+}
+
+// dispatch uploads one uniform buffer of render params, runs the compute
+// shader over the tile, and reads back the iteration-count and final-z
+// storage buffers into the same shape computeJuliaTile produces, so
+// colorTile doesn't need to know which renderer ran. Storage buffers
+// can't be mapped for CPU reads directly, so the results are copied into
+// a pair of MapRead staging buffers first.
+func (r *gpuRenderer) dispatch(params JuliaParams, tile Tile) ([]int, []complex128, error) {
+	pixelCount := tile.W * tile.H
+	itersSize := uint64(pixelCount * 4)
+	zSize := uint64(pixelCount * 8)
+
+	uniform := encodeGPUParams(params, tile)
+	uniformBuf, err := r.device.CreateBufferInit(&webgpu.BufferInitDescriptor{
+		Contents: uniform,
+		Usage:    webgpu.BufferUsage_Uniform | webgpu.BufferUsage_CopyDst,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer uniformBuf.Release()
+
+	itersBuf, err := r.device.CreateBuffer(&webgpu.BufferDescriptor{
+		Size:  itersSize,
+		Usage: webgpu.BufferUsage_Storage | webgpu.BufferUsage_CopySrc,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer itersBuf.Release()
+
+	zBuf, err := r.device.CreateBuffer(&webgpu.BufferDescriptor{
+		Size:  zSize,
+		Usage: webgpu.BufferUsage_Storage | webgpu.BufferUsage_CopySrc,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zBuf.Release()
+
+	itersStaging, err := r.device.CreateBuffer(&webgpu.BufferDescriptor{
+		Size:  itersSize,
+		Usage: webgpu.BufferUsage_MapRead | webgpu.BufferUsage_CopyDst,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer itersStaging.Release()
+
+	zStaging, err := r.device.CreateBuffer(&webgpu.BufferDescriptor{
+		Size:  zSize,
+		Usage: webgpu.BufferUsage_MapRead | webgpu.BufferUsage_CopyDst,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zStaging.Release()
+
+	bindGroup, err := r.device.CreateBindGroup(&webgpu.BindGroupDescriptor{
+		Layout: r.pipeline.GetBindGroupLayout(0),
+		Entries: []webgpu.BindGroupEntry{
+			{Binding: 0, Buffer: uniformBuf, Size: uint64(len(uniform))},
+			{Binding: 1, Buffer: itersBuf, Size: itersSize},
+			{Binding: 2, Buffer: zBuf, Size: zSize},
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer bindGroup.Release()
+
+	encoder, err := r.device.CreateCommandEncoder(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer encoder.Release()
+
+	pass := encoder.BeginComputePass(nil)
+	pass.SetPipeline(r.pipeline)
+	pass.SetBindGroup(0, bindGroup, nil)
+	pass.DispatchWorkgroups(uint32(math.Ceil(float64(tile.W)/8)), uint32(math.Ceil(float64(tile.H)/8)), 1)
+	if err := pass.End(); err != nil {
+		return nil, nil, err
+	}
+	pass.Release()
+
+	if err := encoder.CopyBufferToBuffer(itersBuf, 0, itersStaging, 0, itersSize); err != nil {
+		return nil, nil, err
+	}
+	if err := encoder.CopyBufferToBuffer(zBuf, 0, zStaging, 0, zSize); err != nil {
+		return nil, nil, err
+	}
+
+	cmdBuf, err := encoder.Finish(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.queue.Submit(cmdBuf)
+
+	itersRaw, err := readBuffer(r.device, itersStaging, itersSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	zRaw, err := readBuffer(r.device, zStaging, zSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iters := make([]int, pixelCount)
+	zs := make([]complex128, pixelCount)
+	for i := 0; i < pixelCount; i++ {
+		iters[i] = int(binary.LittleEndian.Uint32(itersRaw[i*4:]))
+		zr := math.Float32frombits(binary.LittleEndian.Uint32(zRaw[i*8:]))
+		zi := math.Float32frombits(binary.LittleEndian.Uint32(zRaw[i*8+4:]))
+		zs[i] = complex(float64(zr), float64(zi))
+	}
+
+	return iters, zs, nil
+}
+
+// readBuffer maps buf for reading and copies out size bytes. Real
+// go-webgpu has no synchronous readback - MapAsync's completion is
+// delivered through a callback, so this blocks on device.Poll until that
+// callback has fired.
+func readBuffer(device *webgpu.Device, buf *webgpu.Buffer, size uint64) ([]byte, error) {
+	var status webgpu.BufferMapAsyncStatus
+	done := false
+	if err := buf.MapAsync(webgpu.MapMode_Read, 0, size, func(s webgpu.BufferMapAsyncStatus) {
+		status = s
+		done = true
+	}); err != nil {
+		return nil, err
+	}
+
+	for !done {
+		device.Poll(true, nil)
+	}
+	if status != webgpu.BufferMapAsyncStatus_Success {
+		return nil, fmt.Errorf("gpu: buffer map failed: %v", status)
+	}
+
+	out := make([]byte, size)
+	copy(out, buf.GetMappedRange(0, uint(size)))
+	if err := buf.Unmap(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// encodeGPUParams packs the uniform struct laid out in escapeTimeShader,
+// matching WGSL's default (std140-like) alignment for this field set.
+func encodeGPUParams(params JuliaParams, tile Tile) []byte {
+	buf := make([]byte, 48)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(params.Width))
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(params.Height))
+	binary.LittleEndian.PutUint32(buf[8:12], uint32(params.MaxIterations))
+	binary.LittleEndian.PutUint32(buf[12:16], uint32(tile.X))
+	binary.LittleEndian.PutUint32(buf[16:20], uint32(tile.Y))
+	binary.LittleEndian.PutUint32(buf[20:24], uint32(tile.W))
+	binary.LittleEndian.PutUint32(buf[24:28], uint32(tile.H))
+	binary.LittleEndian.PutUint32(buf[28:32], math.Float32bits(float32(params.Zoom)))
+	binary.LittleEndian.PutUint32(buf[32:36], math.Float32bits(float32(params.Center.Real)))
+	binary.LittleEndian.PutUint32(buf[36:40], math.Float32bits(float32(params.Center.Imag)))
+	binary.LittleEndian.PutUint32(buf[40:44], math.Float32bits(float32(params.C.Real)))
+	binary.LittleEndian.PutUint32(buf[44:48], math.Float32bits(float32(params.C.Imag)))
+	return buf
+}
@@ -0,0 +1,12 @@
+//go:build !gpu
+
+package main
+
+import "errors"
+
+// newGPURenderer reports the GPU backend as unavailable in a default
+// build. Build with `-tags gpu` against the go-webgpu dependency to
+// compile in the real implementation from renderer_gpu.go instead.
+func newGPURenderer() (Renderer, error) {
+	return nil, errors.New("built without the gpu tag")
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"image"
+	"image/png"
+	"math/cmplx"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// slippyTileSize is the edge length, in pixels, of a standard XYZ slippy-map
+// tile. It is unrelated to tileSize in main.go, which chunks a single
+// websocket frame rather than a deep-zoom pyramid.
+const slippyTileSize = 256
+
+// slippyTileKey identifies one renderable tile and doubles as the cache
+// key. The key holds the palette's name, not its contents: a palette
+// re-registered under the same name does not invalidate cached tiles, which
+// keep the pixels rendered with the earlier palette.
+type slippyTileKey struct {
+	set           string
+	z, x, y       int
+	coloring      int
+	paletteName   string
+	maxIterations int
+	juliaC        Complex
+}
+
+type tileCacheEntry struct {
+	key  slippyTileKey
+	png  []byte
+	etag string
+}
+
+// tileLRUCache is a fixed-capacity, concurrency-safe LRU keyed by
+// slippyTileKey. Re-visiting a zoom level hits the cache instead of
+// re-running the escape-time loop.
+type tileLRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[slippyTileKey]*list.Element
+}
+
+func newTileLRUCache(capacity int) *tileLRUCache {
+	return &tileLRUCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[slippyTileKey]*list.Element),
+	}
+}
+
+func (c *tileLRUCache) get(key slippyTileKey) (*tileCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*tileCacheEntry), true
+}
+
+func (c *tileLRUCache) put(entry *tileCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[entry.key]; ok {
+		el.Value = entry
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	el := c.ll.PushFront(entry)
+	c.items[entry.key] = el
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*tileCacheEntry).key)
+		}
+	}
+}
+
+var slippyTileCache = newTileLRUCache(2048)
+
+// tileHandler serves /tile/{set}/{z}/{x}/{y}.png, where set is "mandelbrot"
+// or "julia". It is meant to sit behind a Leaflet/OpenLayers XYZ tile layer
+// for deep-zoom exploration, alongside the existing interactive websocket.
+func tileHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/tile/"), ".png")
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 {
+		http.NotFound(w, r)
+		return
+	}
+
+	set := parts[0]
+	if set != "mandelbrot" && set != "julia" {
+		http.Error(w, "unknown set: "+set, http.StatusBadRequest)
+		return
+	}
+
+	z, errZ := strconv.Atoi(parts[1])
+	x, errX := strconv.Atoi(parts[2])
+	y, errY := strconv.Atoi(parts[3])
+	if errZ != nil || errX != nil || errY != nil || z < 1 {
+		http.Error(w, "invalid tile coordinates", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	coloring, _ := strconv.Atoi(query.Get("coloring"))
+	paletteName := query.Get("palette")
+	maxIterations, err := strconv.Atoi(query.Get("maxIterations"))
+	if err != nil || maxIterations <= 0 {
+		maxIterations = 500
+	}
+
+	var juliaC Complex
+	if set == "julia" {
+		juliaC.Real, _ = strconv.ParseFloat(query.Get("cReal"), 64)
+		juliaC.Imag, _ = strconv.ParseFloat(query.Get("cImag"), 64)
+	}
+
+	key := slippyTileKey{
+		set:           set,
+		z:             z,
+		x:             x,
+		y:             y,
+		coloring:      coloring,
+		paletteName:   paletteName,
+		maxIterations: maxIterations,
+		juliaC:        juliaC,
+	}
+
+	entry, ok := slippyTileCache.get(key)
+	if !ok {
+		pixels := renderSlippyTile(set, z, x, y, coloring, paletteName, maxIterations, juliaC)
+		pngBytes, err := encodeTilePNG(pixels)
+		if err != nil {
+			http.Error(w, "could not encode tile", http.StatusInternalServerError)
+			return
+		}
+
+		entry = &tileCacheEntry{key: key, png: pngBytes, etag: tileETag(pngBytes)}
+		slippyTileCache.put(entry)
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", entry.etag)
+
+	if r.Header.Get("If-None-Match") == entry.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Write(entry.png)
+}
+
+func tileETag(data []byte) string {
+	sum := sha1.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// renderSlippyTile renders one slippyTileSize x slippyTileSize tile of
+// either set at XYZ coordinates (z, x, y), reusing the same escape-time
+// loop and ReturnRGBA palettes as the interactive renderer. Tile
+// coordinates are mapped onto the complex plane's [-2, 2] square, halved
+// for every zoom level.
+func renderSlippyTile(set string, z, x, y, coloring int, paletteName string, maxIterations int, juliaC Complex) []byte {
+	data := make([]byte, slippyTileSize*slippyTileSize*4)
+	scale := float64(uint(1) << uint(z-1))
+	c := complex(juliaC.Real, juliaC.Imag)
+
+	for py := 0; py < slippyTileSize; py++ {
+		imag := (float64(py)/slippyTileSize+float64(y))/scale*4 - 2
+		for px := 0; px < slippyTileSize; px++ {
+			real := (float64(px)/slippyTileSize+float64(x))/scale*4 - 2
+
+			var z0 complex128
+			if set == "julia" {
+				z0 = complex(real, imag)
+			} else {
+				c = complex(real, imag)
+			}
+
+			zn := z0
+			var i int
+			for i = 0; i < maxIterations; i++ {
+				if cmplx.Abs(zn) > 2 {
+					break
+				}
+				zn = zn*zn + c
+			}
+
+			index := (py*slippyTileSize + px) * 4
+			col := ReturnRGBA(paletteName, coloring, i, maxIterations, zn)
+			data[index] = col.R
+			data[index+1] = col.G
+			data[index+2] = col.B
+			data[index+3] = col.A
+		}
+	}
+
+	return data
+}
+
+func encodeTilePNG(pixels []byte) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, slippyTileSize, slippyTileSize))
+	copy(img.Pix, pixels)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}